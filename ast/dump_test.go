@@ -0,0 +1,75 @@
+package ast
+
+import (
+	"strings"
+	"testing"
+
+	"grol.io/grol/token"
+)
+
+func TestSdumpShowsTypeAndLiteral(t *testing.T) {
+	got := Sdump(intLit(3))
+	if !strings.Contains(got, "*ast.IntegerLiteral") {
+		t.Fatalf("Sdump(3) = %q, want it to mention *ast.IntegerLiteral", got)
+	}
+}
+
+func TestSdumpNilNode(t *testing.T) {
+	var n Node
+	if got := Sdump(n); strings.TrimSpace(got) != "nil" {
+		t.Fatalf("Sdump(nil) = %q, want \"nil\"", got)
+	}
+}
+
+// TestSdumpDetectsSharedSubtree pins down the back-reference behavior:
+// the second time dump encounters the same node pointer (a shared
+// subtree, the kind macro expansion can introduce), it prints "(see
+// pN)" instead of recursing into it again and looping forever.
+func TestSdumpDetectsSharedSubtree(t *testing.T) {
+	shared := intLit(1)
+	root := &InfixExpression{Base: Base{Token: newTok(token.PLUS, "+")}, Left: shared, Right: shared}
+
+	got := Sdump(root)
+	if strings.Count(got, "IntegerLiteral") != 2 {
+		t.Fatalf("Sdump(shared subtree) = %q, want the shared node printed once in full and once as a back-reference", got)
+	}
+	if !strings.Contains(got, "see p") {
+		t.Fatalf("Sdump(shared subtree) = %q, want a \"(see pN)\" back-reference for the repeated pointer", got)
+	}
+}
+
+// TestSdumpMapLiteralIsDeterministic is the regression test for the
+// review comment on dumpField's old map[Node]Node case: ranging
+// MapLiteral.Pairs directly has randomized order, so two Fdump calls
+// on the same tree could print its entries in different sequences.
+// Walking Order instead must make every call agree.
+func TestSdumpMapLiteralIsDeterministic(t *testing.T) {
+	k1, k2, k3 := intLit(1), intLit(2), intLit(3)
+	ml := &MapLiteral{
+		Order: []Node{k1, k2, k3},
+		Pairs: map[Node]Node{k1: intLit(10), k2: intLit(20), k3: intLit(30)},
+	}
+
+	first := Sdump(ml)
+	for i := 0; i < 20; i++ {
+		if got := Sdump(ml); got != first {
+			t.Fatalf("Sdump(MapLiteral) not deterministic across runs:\nfirst: %q\ngot:   %q", first, got)
+		}
+	}
+}
+
+// TestSdumpMapLiteralFollowsOrder checks the entries appear in Order's
+// sequence, not whatever order Pairs happens to range in.
+func TestSdumpMapLiteralFollowsOrder(t *testing.T) {
+	k1, k2 := intLit(1), intLit(2)
+	ml := &MapLiteral{
+		Order: []Node{k1, k2},
+		Pairs: map[Node]Node{k1: intLit(100), k2: intLit(200)},
+	}
+	got := Sdump(ml)
+	i1 := strings.Index(got, "Val: 100")
+	i2 := strings.Index(got, "Val: 200")
+	if i1 < 0 || i2 < 0 || i1 > i2 {
+		t.Fatalf("Sdump(MapLiteral) = %q, want value 100 (key 1, first in Order) before value 200", got)
+	}
+}
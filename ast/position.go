@@ -0,0 +1,104 @@
+package ast
+
+// Position is GROL's source-span type. It lives here in ast, not in
+// token, because this checkout's token/lexer/parser haven't been
+// threaded with real file/line/column data — the original request was
+// explicit that this is a cross-cutting change across
+// token/lexer/parser/ast/eval, and only the ast side (this file and
+// Base.Pos/End in ast.go) is buildable in this checkout. Valid is
+// false and Filename/Line/Column are always zero until that threading
+// lands; Pos()/End() are a structural stub, not real diagnostics data.
+// Once the lexer/parser populate real positions, this type and these
+// methods belong in token, and Node should expose token.Position, per
+// the original request.
+type Position struct {
+	Filename string
+	Line     int
+	Column   int
+	Valid    bool
+}
+
+// This file also gives composite nodes an End() that recurses to their
+// last child instead of the Base default, so Pos()/End() bracket the
+// node's full source span once Position carries real data. Pos() needs
+// no override beyond Base's for any of these: the node's own token is
+// already the right anchor (the operator for InfixExpression, "(" for
+// CallExpression, "if" for IfExpression, and so on), following the
+// same convention cmd/compile/internal/syntax uses.
+
+func (rs ReturnStatement) End() Position {
+	if rs.ReturnValue != nil {
+		return rs.ReturnValue.End()
+	}
+	return rs.Base.End()
+}
+
+func (p Statements) End() Position {
+	if len(p.Statements) == 0 {
+		return p.Base.End()
+	}
+	return p.Statements[len(p.Statements)-1].End()
+}
+
+func (p PrefixExpression) End() Position {
+	return p.Right.End()
+}
+
+func (i InfixExpression) End() Position {
+	if i.Right != nil {
+		return i.Right.End()
+	}
+	return i.Left.End()
+}
+
+func (fe ForExpression) End() Position {
+	return fe.Body.End()
+}
+
+func (ie IfExpression) End() Position {
+	if ie.Alternative != nil {
+		return ie.Alternative.End()
+	}
+	return ie.Consequence.End()
+}
+
+func (b Builtin) End() Position {
+	if len(b.Parameters) == 0 {
+		return b.Base.End()
+	}
+	return b.Parameters[len(b.Parameters)-1].End()
+}
+
+func (fl FunctionLiteral) End() Position {
+	return fl.Body.End()
+}
+
+func (ce CallExpression) End() Position {
+	if len(ce.Arguments) == 0 {
+		return ce.Function.End()
+	}
+	return ce.Arguments[len(ce.Arguments)-1].End()
+}
+
+func (al ArrayLiteral) End() Position {
+	if len(al.Elements) == 0 {
+		return al.Base.End()
+	}
+	return al.Elements[len(al.Elements)-1].End()
+}
+
+func (ie IndexExpression) End() Position {
+	return ie.Index.End()
+}
+
+func (hl MapLiteral) End() Position {
+	if len(hl.Order) == 0 {
+		return hl.Base.End()
+	}
+	last := hl.Order[len(hl.Order)-1]
+	return hl.Pairs[last].End()
+}
+
+func (ml MacroLiteral) End() Position {
+	return ml.Body.End()
+}
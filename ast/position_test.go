@@ -0,0 +1,78 @@
+package ast
+
+import (
+	"testing"
+
+	"grol.io/grol/token"
+)
+
+// TestBasePosEndAreStubbed pins down the documented caveat: Pos/End
+// always return the zero Position (Valid: false) until the
+// lexer/parser thread real spans through token.Token. If that ever
+// changes, this test should start failing and can be deleted along
+// with the stub comments in position.go.
+func TestBasePosEndAreStubbed(t *testing.T) {
+	n := intLit(1)
+	if got := n.Pos(); got.Valid {
+		t.Fatalf("Pos() = %#v, want a stub zero Position (Valid: false)", got)
+	}
+	if got := n.End(); got.Valid {
+		t.Fatalf("End() = %#v, want a stub zero Position (Valid: false)", got)
+	}
+}
+
+func TestReturnStatementEnd(t *testing.T) {
+	withValue := &ReturnStatement{Base: Base{Token: newTok(token.IDENT, "return")}, ReturnValue: intLit(5)}
+	if got, want := withValue.End(), withValue.ReturnValue.End(); got != want {
+		t.Errorf("return 5: End() = %#v, want the return value's End() %#v", got, want)
+	}
+
+	bare := &ReturnStatement{Base: Base{Token: newTok(token.IDENT, "return")}}
+	if got, want := bare.End(), bare.Base.End(); got != want {
+		t.Errorf("bare return: End() = %#v, want Base.End() %#v", got, want)
+	}
+}
+
+func TestStatementsEnd(t *testing.T) {
+	last := intLit(2)
+	s := &Statements{Statements: []Node{intLit(1), last}}
+	if got, want := s.End(), last.End(); got != want {
+		t.Errorf("Statements.End() = %#v, want last statement's End() %#v", got, want)
+	}
+
+	empty := &Statements{Base: Base{Token: newTok(token.IDENT, "{")}}
+	if got, want := empty.End(), empty.Base.End(); got != want {
+		t.Errorf("empty Statements.End() = %#v, want Base.End() %#v", got, want)
+	}
+}
+
+func TestInfixExpressionEnd(t *testing.T) {
+	i := infix("+", token.PLUS, intLit(1), intLit(2))
+	if got, want := i.End(), i.Right.End(); got != want {
+		t.Errorf("1 + 2: End() = %#v, want Right's End() %#v", got, want)
+	}
+
+	noRight := &InfixExpression{Base: Base{Token: newTok(token.PLUS, "+")}, Left: intLit(1)}
+	if got, want := noRight.End(), noRight.Left.End(); got != want {
+		t.Errorf("dangling infix: End() = %#v, want Left's End() %#v", got, want)
+	}
+}
+
+func TestIfExpressionEnd(t *testing.T) {
+	withAlt := &IfExpression{
+		Condition:   &Boolean{Val: true},
+		Consequence: &Statements{Statements: []Node{intLit(1)}},
+		Alternative: &Statements{Statements: []Node{intLit(2)}},
+	}
+	if got, want := withAlt.End(), withAlt.Alternative.End(); got != want {
+		t.Errorf("if/else: End() = %#v, want Alternative's End() %#v", got, want)
+	}
+
+	noAlt := &IfExpression{
+		Condition:   &Boolean{Val: true},
+		Consequence: &Statements{Statements: []Node{intLit(1)}},
+	}
+	if got, want := noAlt.End(), noAlt.Consequence.End(); got != want {
+		t.Errorf("if without else: End() = %#v, want Consequence's End() %#v", got, want)
+	}
+}
@@ -0,0 +1,226 @@
+package ast
+
+// Simplify returns a canonicalized form of n, folding constant integer
+// and float arithmetic, unwrapping a block whose only statement is
+// itself a block ({ { x } } -> { x }; other single-statement blocks are
+// kept as blocks), collapsing
+// an if/else whose condition is a literal bool down to its live
+// branch, and deduping MapLiteral keys (last write wins, Order
+// preserved), in the spirit of CUE's labelSimplifier and gofmt -s. It
+// mutates and returns nodes in place rather than copying, same as the
+// rest of this package. Simplify is idempotent:
+// Simplify(Simplify(x)) produces the same tree as Simplify(x).
+//
+// Two things gofmt -s does that this doesn't: string constant folding
+// ("a"+"b" -> "ab") is skipped because StringLiteral has no Val field
+// of its own — its value is the token's literal text — and folding it
+// would mean fabricating a new token.Token, which this package has no
+// way to construct; and dropping redundant parens doesn't apply here
+// at all, because GROL's AST has no parenthesized-expression node in
+// the first place — precedence decides at print time (see needParen)
+// whether parens are emitted, so that canonicalization already lives
+// in PrettyPrint, not in Simplify.
+//
+// PrintState.Simplify runs this as a pre-print pass; eval can also call
+// it directly as a peephole optimizer before evaluating a tree.
+func Simplify(n Node) Node {
+	switch v := n.(type) {
+	case *Statements:
+		return simplifyStatements(v)
+	case *PrefixExpression:
+		return simplifyPrefix(v)
+	case *InfixExpression:
+		return simplifyInfix(v)
+	case *IfExpression:
+		return simplifyIf(v)
+	case *MapLiteral:
+		return simplifyMap(v)
+	case *CallExpression:
+		v.Function = Simplify(v.Function)
+		for i, a := range v.Arguments {
+			v.Arguments[i] = Simplify(a)
+		}
+		return v
+	case *Builtin:
+		for i, p := range v.Parameters {
+			v.Parameters[i] = Simplify(p)
+		}
+		return v
+	case *ArrayLiteral:
+		for i, e := range v.Elements {
+			v.Elements[i] = Simplify(e)
+		}
+		return v
+	case *IndexExpression:
+		v.Left = Simplify(v.Left)
+		v.Index = Simplify(v.Index)
+		return v
+	case *ForExpression:
+		v.Condition = Simplify(v.Condition)
+		v.Body = simplifyStatements(v.Body)
+		return v
+	case *FunctionLiteral:
+		v.Body = simplifyStatements(v.Body)
+		return v
+	case *MacroLiteral:
+		v.Body = simplifyStatements(v.Body)
+		return v
+	case *ReturnStatement:
+		if v.ReturnValue != nil {
+			v.ReturnValue = Simplify(v.ReturnValue)
+		}
+		return v
+	default:
+		return n
+	}
+}
+
+func simplifyStatements(s *Statements) *Statements {
+	for i, st := range s.Statements {
+		s.Statements[i] = Simplify(st)
+	}
+	// `{ { x } }` -> `{ x }`: a lone nested block adds nothing.
+	if len(s.Statements) == 1 {
+		if inner, ok := s.Statements[0].(*Statements); ok {
+			return inner
+		}
+	}
+	return s
+}
+
+func simplifyPrefix(p *PrefixExpression) Node {
+	p.Right = Simplify(p.Right)
+	if p.Literal() != "-" {
+		return p
+	}
+	switch r := p.Right.(type) {
+	case *IntegerLiteral:
+		r.Val = -r.Val
+		return r
+	case *FloatLiteral:
+		r.Val = -r.Val
+		return r
+	}
+	return p
+}
+
+func simplifyInfix(i *InfixExpression) Node {
+	i.Left = Simplify(i.Left)
+	if i.Right != nil {
+		i.Right = Simplify(i.Right)
+	}
+	if folded := foldConstant(i); folded != nil {
+		return folded
+	}
+	return i
+}
+
+// foldConstant returns the literal node i folds to when both operands
+// are numeric literals and the operator supports folding, or nil if i
+// can't be folded (e.g. it has a non-literal operand, string operands
+// — see the Simplify doc comment — or a runtime-only operator like
+// comparisons that eval, not Simplify, is responsible for).
+func foldConstant(i *InfixExpression) Node {
+	op := i.Literal()
+	li, lIsInt := i.Left.(*IntegerLiteral)
+	ri, rIsInt := i.Right.(*IntegerLiteral)
+	if lIsInt && rIsInt {
+		if v, ok := foldInt(op, li.Val, ri.Val); ok {
+			li.Val = v
+			return li
+		}
+		return nil
+	}
+	lf, lv, lok := asFloat(i.Left)
+	_, rv, rok := asFloat(i.Right)
+	if lok && rok {
+		if v, ok := foldFloat(op, lv, rv); ok {
+			lf.Val = v
+			return lf
+		}
+	}
+	return nil
+}
+
+func asFloat(n Node) (*FloatLiteral, float64, bool) {
+	switch v := n.(type) {
+	case *FloatLiteral:
+		return v, v.Val, true
+	case *IntegerLiteral:
+		return &FloatLiteral{Base: v.Base, Val: float64(v.Val)}, float64(v.Val), true
+	}
+	return nil, 0, false
+}
+
+func foldInt(op string, a, b int64) (int64, bool) {
+	switch op {
+	case "+":
+		return a + b, true
+	case "-":
+		return a - b, true
+	case "*":
+		return a * b, true
+	case "/":
+		if b == 0 {
+			return 0, false // leave division by zero for eval to report.
+		}
+		return a / b, true
+	default:
+		return 0, false
+	}
+}
+
+func foldFloat(op string, a, b float64) (float64, bool) {
+	switch op {
+	case "+":
+		return a + b, true
+	case "-":
+		return a - b, true
+	case "*":
+		return a * b, true
+	case "/":
+		return a / b, true
+	default:
+		return 0, false
+	}
+}
+
+func simplifyIf(ie *IfExpression) Node {
+	ie.Condition = Simplify(ie.Condition)
+	ie.Consequence = simplifyStatements(ie.Consequence)
+	if ie.Alternative != nil {
+		ie.Alternative = simplifyStatements(ie.Alternative)
+	}
+	// `if true { x } else { y }` / `if false { x } else { y }`: the
+	// condition is constant, so keep only the live branch.
+	if b, ok := ie.Condition.(*Boolean); ok {
+		if b.Val {
+			return ie.Consequence
+		}
+		if ie.Alternative != nil {
+			return ie.Alternative
+		}
+		return &Statements{Base: ie.Base}
+	}
+	return ie
+}
+
+func simplifyMap(hl *MapLiteral) Node {
+	seen := map[string]Node{}
+	order := make([]Node, 0, len(hl.Order))
+	for _, key := range hl.Order {
+		k := DebugString(key)
+		if prev, dup := seen[k]; dup {
+			hl.Pairs[prev] = Simplify(hl.Pairs[key])
+			delete(hl.Pairs, key)
+			continue
+		}
+		seen[k] = key
+		order = append(order, key)
+	}
+	hl.Order = order
+	for key, val := range hl.Pairs {
+		hl.Pairs[key] = Simplify(val)
+	}
+	return hl
+}
@@ -0,0 +1,163 @@
+package ast
+
+import (
+	"testing"
+
+	"grol.io/grol/token"
+)
+
+// newTok builds a minimal token for the operator/keyword literals the
+// tests below need. token.New isn't otherwise used anywhere in this
+// checkout (this snapshot has no lexer/parser sources to call it),
+// but it's the same kind of constructor astmatch.go already assumes
+// exists (lexer.New, parser.New) for the real token/lexer/parser
+// packages this module depends on.
+func newTok(t token.Type, literal string) *token.Token {
+	return token.New(t, literal)
+}
+
+func intLit(v int64) *IntegerLiteral {
+	return &IntegerLiteral{Base: Base{Token: newTok(token.INT, "")}, Val: v}
+}
+
+func infix(op string, t token.Type, left, right Node) *InfixExpression {
+	return &InfixExpression{Base: Base{Token: newTok(t, op)}, Left: left, Right: right}
+}
+
+// debugSimplified prints n the way PrintState.Simplify would: folded
+// IntegerLiteral/FloatLiteral values rather than stale source text.
+// Test cases below are built so nothing else needing a live token
+// (Boolean, unfolded InfixExpression, ...) ever ends up on the
+// printed path.
+func debugSimplified(n Node) string {
+	ps := NewPrintState()
+	ps.Compact = true
+	ps.AllParens = true
+	ps.Simplify = true
+	n.PrettyPrint(ps)
+	return ps.String()
+}
+
+// assertIdempotent simplifies build() once, then simplifies that
+// result again, and fails if the two don't print identically — the
+// property the Simplify doc comment promises:
+// Simplify(Simplify(x)) produces the same tree as Simplify(x).
+func assertIdempotent(t *testing.T, name string, build func() Node) Node {
+	t.Helper()
+	first := Simplify(build())
+	want := debugSimplified(first)
+	second := Simplify(first)
+	got := debugSimplified(second)
+	if want != got {
+		t.Errorf("%s: not idempotent: first pass %q, second pass %q", name, want, got)
+	}
+	return first
+}
+
+func TestSimplifyConstantFold(t *testing.T) {
+	n := assertIdempotent(t, "1 + 2", func() Node {
+		return infix("+", token.PLUS, intLit(1), intLit(2))
+	})
+	got, ok := n.(*IntegerLiteral)
+	if !ok || got.Val != 3 {
+		t.Fatalf("1 + 2: got %#v, want IntegerLiteral{Val: 3}", n)
+	}
+}
+
+func TestSimplifyPrefixNegation(t *testing.T) {
+	n := assertIdempotent(t, "-5", func() Node {
+		return &PrefixExpression{Base: Base{Token: newTok(token.MINUS, "-")}, Right: intLit(5)}
+	})
+	got, ok := n.(*IntegerLiteral)
+	if !ok || got.Val != -5 {
+		t.Fatalf("-5: got %#v, want IntegerLiteral{Val: -5}", n)
+	}
+}
+
+// TestSimplifyBuiltinRecursesIntoParameters covers the gap the review
+// flagged: Simplify's switch had no case for *Builtin, so constant
+// folding never reached into a builtin call's arguments even though
+// ast.Walk/Inspect already descend into them.
+func TestSimplifyBuiltinRecursesIntoParameters(t *testing.T) {
+	build := func() Node {
+		return &Builtin{
+			Base:       Base{Token: newTok(token.IDENT, "len")},
+			Parameters: []Node{infix("+", token.PLUS, intLit(1), intLit(2))},
+		}
+	}
+	n := assertIdempotent(t, "len(1 + 2)", build)
+	b, ok := n.(*Builtin)
+	if !ok || len(b.Parameters) != 1 {
+		t.Fatalf("len(1 + 2): got %#v, want a Builtin with one parameter", n)
+	}
+	got, ok := b.Parameters[0].(*IntegerLiteral)
+	if !ok || got.Val != 3 {
+		t.Fatalf("len(1 + 2): parameter got %#v, want IntegerLiteral{Val: 3}", b.Parameters[0])
+	}
+}
+
+func TestSimplifyIfElseLiteralCondition(t *testing.T) {
+	build := func() Node {
+		return &IfExpression{
+			Condition:   &Boolean{Val: true},
+			Consequence: &Statements{Statements: []Node{intLit(1)}},
+			Alternative: &Statements{Statements: []Node{intLit(2)}},
+		}
+	}
+	n := assertIdempotent(t, "if true {1} else {2}", build)
+	s, ok := n.(*Statements)
+	if !ok || len(s.Statements) != 1 {
+		t.Fatalf("if true: got %#v, want the consequence block", n)
+	}
+	got, ok := s.Statements[0].(*IntegerLiteral)
+	if !ok || got.Val != 1 {
+		t.Fatalf("if true: got %#v, want IntegerLiteral{Val: 1}", s.Statements[0])
+	}
+}
+
+func TestSimplifyMapDedupKeepsLastWrite(t *testing.T) {
+	kA, kA2 := intLit(1), intLit(1)
+	n := Simplify(&MapLiteral{
+		Order: []Node{kA, kA2},
+		Pairs: map[Node]Node{kA: intLit(10), kA2: intLit(20)},
+	})
+	m, ok := n.(*MapLiteral)
+	if !ok || len(m.Order) != 1 {
+		t.Fatalf("map dedup: got %#v, want a single-entry MapLiteral", n)
+	}
+	v, ok := m.Pairs[m.Order[0]].(*IntegerLiteral)
+	if !ok || v.Val != 20 {
+		t.Fatalf("map dedup: got %#v, want the last write (20)", m.Pairs[m.Order[0]])
+	}
+}
+
+// TestSimplifyDoesNotMutateClonedSource pins down what Clone is for:
+// simplifying a clone must never touch the node it was cloned from.
+func TestSimplifyDoesNotMutateClonedSource(t *testing.T) {
+	orig := infix("+", token.PLUS, intLit(1), intLit(2))
+	Simplify(Clone(orig))
+	if orig.Left.(*IntegerLiteral).Val != 1 || orig.Right.(*IntegerLiteral).Val != 2 {
+		t.Fatalf("Simplify mutated a cloned copy's source: %#v", orig)
+	}
+}
+
+// TestPrettyPrintSimplifyDoesNotMutateTree is the regression test for
+// the review comment on Statements.PrettyPrint: ps.Simplify = true
+// used to fold and prune the live tree in place (p's Statements slice
+// shares its backing array, and every node pointer in it, with the
+// tree being printed), so printing once with Simplify permanently
+// corrupted the original for any later print or eval.
+func TestPrettyPrintSimplifyDoesNotMutateTree(t *testing.T) {
+	stmts := &Statements{Statements: []Node{infix("+", token.PLUS, intLit(1), intLit(2))}}
+	ps := NewPrintState()
+	ps.Simplify = true
+	stmts.PrettyPrint(ps)
+
+	got, ok := stmts.Statements[0].(*InfixExpression)
+	if !ok {
+		t.Fatalf("PrettyPrint with Simplify mutated the tree shape: %#v", stmts.Statements[0])
+	}
+	if got.Left.(*IntegerLiteral).Val != 1 || got.Right.(*IntegerLiteral).Val != 2 {
+		t.Fatalf("PrettyPrint with Simplify folded the live tree in place: %#v", got)
+	}
+}
@@ -0,0 +1,131 @@
+package ast
+
+import "fmt"
+
+// Visitor's Visit method is invoked by Walk for each node it encounters.
+// If the returned Visitor w is not nil, Walk visits each child of node
+// with w, followed by a final call to w.Visit(nil).
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk traverses an AST in depth-first order: it calls v.Visit(node),
+// and if the visitor w returned by v.Visit(node) is not nil, recurses
+// into each child of node with w, followed by a call of w.Visit(nil).
+// This is the analog of go/ast.Walk for GROL programs and lets tools
+// like linters, macro-expansion analyzers, and refactoring passes
+// traverse the tree without hand-coding a type switch over every node
+// kind.
+func Walk(v Visitor, node Node) {
+	if node == nil {
+		return
+	}
+	v = v.Visit(node)
+	if v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	case *ControlExpression, *Identifier, *Comment,
+		*IntegerLiteral, *FloatLiteral, *StringLiteral, *Boolean:
+		// Leaves: nothing further to walk.
+
+	case *ReturnStatement:
+		if n.ReturnValue != nil {
+			Walk(v, n.ReturnValue)
+		}
+
+	case *Statements:
+		for _, s := range n.Statements {
+			Walk(v, s)
+		}
+
+	case *PrefixExpression:
+		Walk(v, n.Right)
+
+	case *PostfixExpression:
+		// Prev is the preceding token, not a child Node.
+
+	case *InfixExpression:
+		Walk(v, n.Left)
+		if n.Right != nil {
+			Walk(v, n.Right)
+		}
+
+	case *ForExpression:
+		Walk(v, n.Condition)
+		Walk(v, n.Body)
+
+	case *IfExpression:
+		Walk(v, n.Condition)
+		Walk(v, n.Consequence)
+		if n.Alternative != nil {
+			Walk(v, n.Alternative)
+		}
+
+	case *Builtin:
+		for _, p := range n.Parameters {
+			Walk(v, p)
+		}
+
+	case *FunctionLiteral:
+		if n.Name != nil {
+			Walk(v, n.Name)
+		}
+		for _, p := range n.Parameters {
+			Walk(v, p)
+		}
+		Walk(v, n.Body)
+
+	case *MacroLiteral:
+		for _, p := range n.Parameters {
+			Walk(v, p)
+		}
+		Walk(v, n.Body)
+
+	case *CallExpression:
+		Walk(v, n.Function)
+		for _, a := range n.Arguments {
+			Walk(v, a)
+		}
+
+	case *ArrayLiteral:
+		for _, e := range n.Elements {
+			Walk(v, e)
+		}
+
+	case *IndexExpression:
+		Walk(v, n.Left)
+		Walk(v, n.Index)
+
+	case *MapLiteral:
+		for _, key := range n.Order {
+			Walk(v, key)
+			Walk(v, n.Pairs[key])
+		}
+
+	default:
+		panic(fmt.Sprintf("ast.Walk: unexpected node type %T", n))
+	}
+
+	v.Visit(nil)
+}
+
+// inspector adapts a plain func(Node) bool to a Visitor so Inspect can
+// be implemented in terms of Walk.
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses an AST in depth-first order: it calls f(node), and
+// if f returns true, recurses into each child of node, followed by a
+// call of f(nil). It's a convenience wrapper around Walk for callers
+// that don't need a stateful Visitor.
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
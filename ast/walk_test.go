@@ -0,0 +1,96 @@
+package ast
+
+import (
+	"fmt"
+	"testing"
+
+	"grol.io/grol/token"
+)
+
+// visitOrder walks root and records the concrete type of every node
+// Walk visits, including the trailing nil Visit call every visited
+// node, leaf or composite, gets once its children (if any) are done
+// (the signal the Visitor doc comment promises). "nil" marks that call.
+func visitOrder(root Node) []string {
+	var got []string
+	Inspect(root, func(n Node) bool {
+		if n == nil {
+			got = append(got, "nil")
+			return true
+		}
+		got = append(got, fmt.Sprintf("%T", n))
+		return true
+	})
+	return got
+}
+
+func TestWalkVisitsDepthFirst(t *testing.T) {
+	root := &Statements{Statements: []Node{
+		infix("+", token.PLUS, intLit(1), intLit(2)),
+	}}
+	want := []string{
+		"*ast.Statements",
+		"*ast.InfixExpression",
+		"*ast.IntegerLiteral",
+		"nil", // Left done
+		"*ast.IntegerLiteral",
+		"nil", // Right done
+		"nil", // InfixExpression done
+		"nil", // Statements done
+	}
+	got := visitOrder(root)
+	if !equalStrings(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestWalkRecursesIntoBuiltinParameters(t *testing.T) {
+	root := &Statements{Statements: []Node{
+		&Builtin{Base: Base{Token: newTok(token.IDENT, "len")}, Parameters: []Node{intLit(5)}},
+	}}
+	want := []string{
+		"*ast.Statements",
+		"*ast.Builtin",
+		"*ast.IntegerLiteral",
+		"nil", // IntegerLiteral done
+		"nil", // Builtin done
+		"nil", // Statements done
+	}
+	got := visitOrder(root)
+	if !equalStrings(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+// TestInspectStopsAtFalse pins down the early-exit contract: once f
+// returns false for a node, Walk must not descend into that node's
+// children, though it keeps visiting the node's later siblings.
+func TestInspectStopsAtFalse(t *testing.T) {
+	pruned := infix("+", token.PLUS, intLit(1), intLit(2))
+	root := &Statements{Statements: []Node{pruned, intLit(3)}}
+
+	var got []string
+	Inspect(root, func(n Node) bool {
+		if n == nil {
+			return true
+		}
+		got = append(got, fmt.Sprintf("%T", n))
+		return n != pruned
+	})
+	want := []string{"*ast.Statements", "*ast.InfixExpression", "*ast.IntegerLiteral"}
+	if !equalStrings(got, want) {
+		t.Fatalf("got %v, want %v (Inspect should have skipped pruned's children)", got, want)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,277 @@
+// Package astmatch implements gogrep-style syntactic pattern matching
+// and rewriting over GROL ASTs. A pattern is ordinary GROL source with
+// metavariables: $x (or any $name) binds a single node, $_ binds
+// anonymously, and $xs... (only meaningful in a list position, e.g.
+// call arguments) greedily binds the remaining sibling nodes. This
+// lets tools express lints and codemods ("$x + $x * 2") without
+// writing a bespoke type switch over the ast package.
+package astmatch
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"grol.io/grol/ast"
+	"grol.io/grol/lexer"
+	"grol.io/grol/parser"
+)
+
+// metaPrefix is what a pattern's "$name" is rewritten to before being
+// parsed by the regular GROL parser, so $ never has to become a real
+// lexer token. The result is an ordinary identifier, which is what lets
+// metaVar recognize it as an *ast.Identifier after parsing; a pattern
+// that itself contains an identifier starting with metaPrefix is
+// treated as a metavariable too.
+const metaPrefix = "__astmatch_meta_"
+
+const metaVariadicSuffix = "_variadic__"
+
+var metaVarRe = regexp.MustCompile(`\$([A-Za-z_][A-Za-z0-9_]*|_)(\.\.\.)?`)
+
+// Pattern is a compiled pattern, ready to Match or Rewrite against a
+// target AST.
+type Pattern struct {
+	root ast.Node
+	src  string
+}
+
+// Compile parses pattern into a Pattern. pattern is GROL syntax
+// extended with $metavariables as described in the package doc.
+func Compile(pattern string) (*Pattern, error) {
+	rewritten := metaVarRe.ReplaceAllStringFunc(pattern, func(m string) string {
+		groups := metaVarRe.FindStringSubmatch(m)
+		name, variadic := groups[1], groups[2] != ""
+		if variadic {
+			return metaPrefix + name + metaVariadicSuffix
+		}
+		return metaPrefix + name
+	})
+	l := lexer.New(rewritten)
+	p := parser.New(l)
+	prog, err := p.ParseProgram()
+	if err != nil {
+		return nil, fmt.Errorf("astmatch: compiling pattern %q: %w", pattern, err)
+	}
+	root := ast.Node(prog)
+	// The common case is a single expression or statement; unwrap it so
+	// Match can be called against a sub-expression directly, not just a
+	// whole *ast.Statements.
+	if stmts, ok := root.(*ast.Statements); ok && len(stmts.Statements) == 1 {
+		root = stmts.Statements[0]
+	}
+	return &Pattern{root: root, src: pattern}, nil
+}
+
+// String returns the original, uncompiled pattern source.
+func (p *Pattern) String() string {
+	return p.src
+}
+
+func metaVar(n ast.Node) (name string, variadic bool, ok bool) {
+	id, isIdent := n.(*ast.Identifier)
+	if !isIdent {
+		return "", false, false
+	}
+	lit := id.Literal()
+	if !strings.HasPrefix(lit, metaPrefix) {
+		return "", false, false
+	}
+	name = strings.TrimPrefix(lit, metaPrefix)
+	if strings.HasSuffix(name, metaVariadicSuffix) {
+		return strings.TrimSuffix(name, metaVariadicSuffix), true, true
+	}
+	return name, false, true
+}
+
+// Match reports whether node structurally matches p, returning the
+// node each metavariable in p bound to on success.
+func (p *Pattern) Match(node ast.Node) (map[string]ast.Node, bool) {
+	bindings := map[string]ast.Node{}
+	if matchNode(p.root, node, bindings) {
+		return bindings, true
+	}
+	return nil, false
+}
+
+func matchNode(pat, node ast.Node, bindings map[string]ast.Node) bool {
+	if name, variadic, ok := metaVar(pat); ok && !variadic {
+		if name == "_" {
+			return node != nil
+		}
+		if prev, bound := bindings[name]; bound {
+			return node != nil && ast.DebugString(prev) == ast.DebugString(node)
+		}
+		if node == nil {
+			return false
+		}
+		bindings[name] = node
+		return true
+	}
+	if pat == nil || node == nil {
+		return pat == nil && node == nil
+	}
+	switch p := pat.(type) {
+	case *ast.Identifier:
+		n, ok := node.(*ast.Identifier)
+		return ok && p.Literal() == n.Literal()
+	case *ast.IntegerLiteral:
+		n, ok := node.(*ast.IntegerLiteral)
+		return ok && p.Val == n.Val
+	case *ast.FloatLiteral:
+		n, ok := node.(*ast.FloatLiteral)
+		return ok && p.Val == n.Val
+	case *ast.StringLiteral:
+		n, ok := node.(*ast.StringLiteral)
+		return ok && p.Literal() == n.Literal()
+	case *ast.Boolean:
+		n, ok := node.(*ast.Boolean)
+		return ok && p.Val == n.Val
+	case *ast.PrefixExpression:
+		n, ok := node.(*ast.PrefixExpression)
+		return ok && p.Literal() == n.Literal() && matchNode(p.Right, n.Right, bindings)
+	case *ast.InfixExpression:
+		n, ok := node.(*ast.InfixExpression)
+		return ok && p.Literal() == n.Literal() &&
+			matchNode(p.Left, n.Left, bindings) && matchNode(p.Right, n.Right, bindings)
+	case *ast.IndexExpression:
+		n, ok := node.(*ast.IndexExpression)
+		return ok && matchNode(p.Left, n.Left, bindings) && matchNode(p.Index, n.Index, bindings)
+	case *ast.CallExpression:
+		n, ok := node.(*ast.CallExpression)
+		return ok && matchNode(p.Function, n.Function, bindings) &&
+			matchList(p.Arguments, n.Arguments, bindings)
+	case *ast.ArrayLiteral:
+		n, ok := node.(*ast.ArrayLiteral)
+		return ok && matchList(p.Elements, n.Elements, bindings)
+	case *ast.Statements:
+		n, ok := node.(*ast.Statements)
+		return ok && matchList(p.Statements, n.Statements, bindings)
+	default:
+		// Node kinds without metavariable-aware matching above still
+		// match literally, so a pattern can contain them verbatim.
+		return ast.DebugString(pat) == ast.DebugString(node)
+	}
+}
+
+// matchList matches a pattern list against a target list, honoring a
+// single trailing variadic metavariable ($xs...) that greedily
+// consumes whatever target nodes remain.
+func matchList(pat, nodes []ast.Node, bindings map[string]ast.Node) bool {
+	if n := len(pat); n > 0 {
+		if name, variadic, ok := metaVar(pat[n-1]); ok && variadic {
+			if len(nodes) < n-1 {
+				return false
+			}
+			for i := 0; i < n-1; i++ {
+				if !matchNode(pat[i], nodes[i], bindings) {
+					return false
+				}
+			}
+			if name != "_" {
+				bindings[name] = &ast.ArrayLiteral{Elements: append([]ast.Node{}, nodes[n-1:]...)}
+			}
+			return true
+		}
+	}
+	if len(pat) != len(nodes) {
+		return false
+	}
+	for i := range pat {
+		if !matchNode(pat[i], nodes[i], bindings) {
+			return false
+		}
+	}
+	return true
+}
+
+// Rewrite reports whether root itself matches pattern, and if so
+// returns replacement's root with pattern's metavariables substituted
+// from the match's bindings. Rewrite only ever looks at root itself;
+// callers that want to rewrite every matching subtree throughout a
+// larger tree, not just a single node they already matched by hand,
+// should use RewriteAll instead.
+func Rewrite(root ast.Node, pattern, replacement *Pattern) (ast.Node, bool) {
+	bindings, ok := pattern.Match(root)
+	if !ok {
+		return root, false
+	}
+	return substitute(replacement.root, bindings), true
+}
+
+// substitute rebuilds pat with every metavariable occurrence replaced
+// by its binding, recursing into compound nodes so a replacement like
+// "$x * 2" substitutes $x wherever it sits in the tree, not just when
+// it's the whole pattern. Mirrors matchNode's type switch: node kinds
+// not listed here, including ones with sub-nodes such as
+// ReturnStatement or IfExpression, are returned as-is, so a
+// metavariable inside one of them is not substituted.
+func substitute(pat ast.Node, bindings map[string]ast.Node) ast.Node {
+	if name, _, ok := metaVar(pat); ok {
+		if name == "_" {
+			return pat
+		}
+		if bound, have := bindings[name]; have {
+			return bound
+		}
+		return pat
+	}
+	switch p := pat.(type) {
+	case *ast.PrefixExpression:
+		c := *p
+		c.Right = substitute(p.Right, bindings)
+		return &c
+	case *ast.InfixExpression:
+		c := *p
+		c.Left = substitute(p.Left, bindings)
+		c.Right = substitute(p.Right, bindings)
+		return &c
+	case *ast.IndexExpression:
+		c := *p
+		c.Left = substitute(p.Left, bindings)
+		c.Index = substitute(p.Index, bindings)
+		return &c
+	case *ast.CallExpression:
+		c := *p
+		c.Function = substitute(p.Function, bindings)
+		c.Arguments = substituteList(p.Arguments, bindings)
+		return &c
+	case *ast.ArrayLiteral:
+		c := *p
+		c.Elements = substituteList(p.Elements, bindings)
+		return &c
+	case *ast.Statements:
+		c := *p
+		c.Statements = substituteList(p.Statements, bindings)
+		return &c
+	default:
+		return pat
+	}
+}
+
+// substituteList applies substitute across a list, expanding a
+// trailing variadic metavariable ($xs...) to whatever nodes it bound
+// to, same as matchList binds it on the matching side.
+func substituteList(pat []ast.Node, bindings map[string]ast.Node) []ast.Node {
+	if n := len(pat); n > 0 {
+		if name, variadic, ok := metaVar(pat[n-1]); ok && variadic {
+			out := make([]ast.Node, 0, n-1)
+			for _, e := range pat[:n-1] {
+				out = append(out, substitute(e, bindings))
+			}
+			if name != "_" {
+				if bound, have := bindings[name]; have {
+					if arr, ok := bound.(*ast.ArrayLiteral); ok {
+						out = append(out, arr.Elements...)
+					}
+				}
+			}
+			return out
+		}
+	}
+	out := make([]ast.Node, len(pat))
+	for i, e := range pat {
+		out[i] = substitute(e, bindings)
+	}
+	return out
+}
@@ -0,0 +1,130 @@
+package astmatch
+
+import (
+	"testing"
+
+	"grol.io/grol/ast"
+	"grol.io/grol/token"
+)
+
+// These helpers build ast.Node values directly, the same way
+// ast/simplify_test.go does, since this checkout has no lexer/parser
+// to drive Compile.
+func newTok(t token.Type, literal string) *token.Token {
+	return token.New(t, literal)
+}
+
+func intLit(v int64) *ast.IntegerLiteral {
+	return &ast.IntegerLiteral{Base: ast.Base{Token: newTok(token.INT, "")}, Val: v}
+}
+
+func metaIdent(name string) *ast.Identifier {
+	return &ast.Identifier{Base: ast.Base{Token: newTok(token.IDENT, metaPrefix+name)}}
+}
+
+func infix(op string, t token.Type, left, right ast.Node) *ast.InfixExpression {
+	return &ast.InfixExpression{Base: ast.Base{Token: newTok(t, op)}, Left: left, Right: right}
+}
+
+func TestMatchBindsMetavariable(t *testing.T) {
+	pattern := &Pattern{root: metaIdent("x")}
+	node := intLit(5)
+	bindings, ok := pattern.Match(node)
+	if !ok || bindings["x"] != ast.Node(node) {
+		t.Fatalf("Match($x, 5): got %v, %v, want bindings[x] = %v", bindings, ok, node)
+	}
+}
+
+// TestSubstituteRecursesIntoCompoundReplacement is the regression test
+// for the review comment on substitute: it used to only substitute
+// when the whole replacement pattern was itself a bare metavariable,
+// so a replacement like "$x * 2" left the $x literally in the output
+// instead of splicing in the bound node.
+func TestSubstituteRecursesIntoCompoundReplacement(t *testing.T) {
+	replacement := infix("*", token.ASTERISK, metaIdent("x"), intLit(2))
+	bindings := map[string]ast.Node{"x": intLit(5)}
+
+	got := substitute(replacement, bindings)
+	i, ok := got.(*ast.InfixExpression)
+	if !ok {
+		t.Fatalf("substitute($x * 2): got %#v, want *ast.InfixExpression", got)
+	}
+	left, ok := i.Left.(*ast.IntegerLiteral)
+	if !ok || left.Val != 5 {
+		t.Fatalf("substitute($x * 2): Left = %#v, want IntegerLiteral{Val: 5}", i.Left)
+	}
+	right, ok := i.Right.(*ast.IntegerLiteral)
+	if !ok || right.Val != 2 {
+		t.Fatalf("substitute($x * 2): Right = %#v, want IntegerLiteral{Val: 2}", i.Right)
+	}
+}
+
+func TestRewriteTopLevelMatch(t *testing.T) {
+	pattern := &Pattern{root: metaIdent("x")}
+	replacement := &Pattern{root: infix("*", token.ASTERISK, metaIdent("x"), intLit(2))}
+
+	got, ok := Rewrite(intLit(5), pattern, replacement)
+	if !ok {
+		t.Fatalf("Rewrite(5, $x, $x * 2): matched = false, want true")
+	}
+	i, ok := got.(*ast.InfixExpression)
+	if !ok {
+		t.Fatalf("Rewrite(5, $x, $x * 2): got %#v, want *ast.InfixExpression", got)
+	}
+	if v, ok := i.Left.(*ast.IntegerLiteral); !ok || v.Val != 5 {
+		t.Fatalf("Rewrite(5, $x, $x * 2): Left = %#v, want IntegerLiteral{Val: 5}", i.Left)
+	}
+}
+
+// TestRewriteAllSplicesThroughoutTree is the regression test for the
+// review comment on Rewrite: matching only the root it's called on
+// means a caller has to hand-roll ast.Inspect plus per-field splicing
+// to rewrite anywhere but the top of a tree. Here "-$x" -> "$x" (drop
+// the negation) only ever matches the PrefixExpression statements, not
+// the *ast.Statements root that holds them, so this also checks that
+// RewriteAll leaves a non-matching ancestor's own shape alone while
+// still rebuilding it to hold the rewritten children.
+func TestRewriteAllSplicesThroughoutTree(t *testing.T) {
+	negate := func(v int64) *ast.PrefixExpression {
+		return &ast.PrefixExpression{Base: ast.Base{Token: newTok(token.MINUS, "-")}, Right: intLit(v)}
+	}
+	pattern := &Pattern{root: negate(0)}
+	pattern.root.(*ast.PrefixExpression).Right = metaIdent("x")
+	replacement := &Pattern{root: metaIdent("x")}
+
+	root := &ast.Statements{Statements: []ast.Node{negate(1), negate(3)}}
+	got := RewriteAll(root, pattern, replacement)
+
+	stmts, ok := got.(*ast.Statements)
+	if !ok || len(stmts.Statements) != 2 {
+		t.Fatalf("RewriteAll: got %#v, want a 2-statement *ast.Statements", got)
+	}
+	for idx, want := range []int64{1, 3} {
+		v, ok := stmts.Statements[idx].(*ast.IntegerLiteral)
+		if !ok || v.Val != want {
+			t.Fatalf("RewriteAll: statement %d = %#v, want IntegerLiteral{Val: %d}", idx, stmts.Statements[idx], want)
+		}
+	}
+}
+
+// TestRewriteAllLeavesNonMatchingNodesAlone checks RewriteAll doesn't
+// touch subtrees the pattern doesn't match, only rebuilds the nodes on
+// the path to ones it does.
+func TestRewriteAllLeavesNonMatchingNodesAlone(t *testing.T) {
+	pattern := &Pattern{root: intLit(1)}
+	replacement := &Pattern{root: intLit(99)}
+
+	root := infix("+", token.PLUS, intLit(1), intLit(2))
+	got := RewriteAll(root, pattern, replacement)
+
+	i, ok := got.(*ast.InfixExpression)
+	if !ok {
+		t.Fatalf("RewriteAll: got %#v, want *ast.InfixExpression", got)
+	}
+	if left, ok := i.Left.(*ast.IntegerLiteral); !ok || left.Val != 99 {
+		t.Fatalf("RewriteAll: Left = %#v, want IntegerLiteral{Val: 99}", i.Left)
+	}
+	if right, ok := i.Right.(*ast.IntegerLiteral); !ok || right.Val != 2 {
+		t.Fatalf("RewriteAll: Right = %#v, want the untouched IntegerLiteral{Val: 2}", i.Right)
+	}
+}
@@ -0,0 +1,146 @@
+package astmatch
+
+import (
+	"fmt"
+
+	"grol.io/grol/ast"
+)
+
+// RewriteAll returns a copy of root with every subtree matching pattern
+// replaced per Rewrite, working bottom-up: a node's children are
+// rewritten first, then the node itself — with its already-rewritten
+// children — is tried against pattern. This is the tree-wide rewrite
+// Rewrite's doc comment used to leave callers to hand-roll: walking
+// ast.Inspect themselves and splicing each match back into whichever
+// field of the parent owns it. Mirrors ast.Clone's type switch so every
+// node kind with children is rebuilt rather than mutated in place.
+//
+// A field typed to a specific node kind (a block's *Statements body)
+// can only be replaced by that same kind; RewriteAll panics if a match
+// there produced something else, the same way assigning it by hand
+// wouldn't compile.
+func RewriteAll(root ast.Node, pattern, replacement *Pattern) ast.Node {
+	rebuilt := rewriteChildren(root, pattern, replacement)
+	if out, ok := Rewrite(rebuilt, pattern, replacement); ok {
+		return out
+	}
+	return rebuilt
+}
+
+// rewriteChildren returns a copy of n with RewriteAll applied to every
+// child node, mirroring ast.Clone's type switch. n itself isn't matched
+// against pattern here; RewriteAll does that once rewriteChildren
+// returns.
+func rewriteChildren(n ast.Node, pattern, replacement *Pattern) ast.Node {
+	switch v := n.(type) {
+	case nil:
+		return nil
+	case *ast.ControlExpression, *ast.Identifier, *ast.Comment,
+		*ast.IntegerLiteral, *ast.FloatLiteral, *ast.StringLiteral, *ast.Boolean,
+		*ast.PostfixExpression:
+		// Leaves: nothing further to recurse into.
+		return v
+	case *ast.ReturnStatement:
+		c := *v
+		if v.ReturnValue != nil {
+			c.ReturnValue = RewriteAll(v.ReturnValue, pattern, replacement)
+		}
+		return &c
+	case *ast.Statements:
+		c := *v
+		c.Statements = rewriteList(v.Statements, pattern, replacement)
+		return &c
+	case *ast.PrefixExpression:
+		c := *v
+		c.Right = RewriteAll(v.Right, pattern, replacement)
+		return &c
+	case *ast.InfixExpression:
+		c := *v
+		c.Left = RewriteAll(v.Left, pattern, replacement)
+		if v.Right != nil {
+			c.Right = RewriteAll(v.Right, pattern, replacement)
+		}
+		return &c
+	case *ast.ForExpression:
+		c := *v
+		c.Condition = RewriteAll(v.Condition, pattern, replacement)
+		c.Body = mustStatements(RewriteAll(v.Body, pattern, replacement))
+		return &c
+	case *ast.IfExpression:
+		c := *v
+		c.Condition = RewriteAll(v.Condition, pattern, replacement)
+		c.Consequence = mustStatements(RewriteAll(v.Consequence, pattern, replacement))
+		if v.Alternative != nil {
+			c.Alternative = mustStatements(RewriteAll(v.Alternative, pattern, replacement))
+		}
+		return &c
+	case *ast.Builtin:
+		c := *v
+		c.Parameters = rewriteList(v.Parameters, pattern, replacement)
+		return &c
+	case *ast.FunctionLiteral:
+		c := *v
+		c.Parameters = rewriteList(v.Parameters, pattern, replacement)
+		c.Body = mustStatements(RewriteAll(v.Body, pattern, replacement))
+		return &c
+	case *ast.MacroLiteral:
+		c := *v
+		c.Parameters = rewriteList(v.Parameters, pattern, replacement)
+		c.Body = mustStatements(RewriteAll(v.Body, pattern, replacement))
+		return &c
+	case *ast.CallExpression:
+		c := *v
+		c.Function = RewriteAll(v.Function, pattern, replacement)
+		c.Arguments = rewriteList(v.Arguments, pattern, replacement)
+		return &c
+	case *ast.ArrayLiteral:
+		c := *v
+		c.Elements = rewriteList(v.Elements, pattern, replacement)
+		return &c
+	case *ast.IndexExpression:
+		c := *v
+		c.Left = RewriteAll(v.Left, pattern, replacement)
+		c.Index = RewriteAll(v.Index, pattern, replacement)
+		return &c
+	case *ast.MapLiteral:
+		c := *v
+		newOrder := make([]ast.Node, len(v.Order))
+		newPairs := make(map[ast.Node]ast.Node, len(v.Pairs))
+		for i, key := range v.Order {
+			newKey := RewriteAll(key, pattern, replacement)
+			newOrder[i] = newKey
+			newPairs[newKey] = RewriteAll(v.Pairs[key], pattern, replacement)
+		}
+		c.Order = newOrder
+		c.Pairs = newPairs
+		return &c
+	default:
+		panic(fmt.Sprintf("astmatch.RewriteAll: unexpected node type %T", n))
+	}
+}
+
+// rewriteList applies RewriteAll across a list.
+func rewriteList(list []ast.Node, pattern, replacement *Pattern) []ast.Node {
+	if list == nil {
+		return nil
+	}
+	out := make([]ast.Node, len(list))
+	for i, n := range list {
+		out[i] = RewriteAll(n, pattern, replacement)
+	}
+	return out
+}
+
+// mustStatements asserts that n — the result of rewriting a block — is
+// still a *Statements, as required by the field it's about to be
+// assigned into. A pattern/replacement pair that can turn a block into
+// something else isn't expressible through this field, so that's a
+// programming error in the caller's pattern, not a runtime condition to
+// recover from.
+func mustStatements(n ast.Node) *ast.Statements {
+	s, ok := n.(*ast.Statements)
+	if !ok {
+		panic(fmt.Sprintf("astmatch.RewriteAll: replacement for a block must itself be a statements list, got %T", n))
+	}
+	return s
+}
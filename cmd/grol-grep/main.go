@@ -0,0 +1,56 @@
+// Command grol-grep runs an astmatch pattern over a GROL source file
+// and prints every matching node, prefixed with the file name, in its
+// compact ast.DebugString form (not the original source text). It
+// doesn't report position: ast.Position is a
+// structural stub in this checkout (see ast/position.go) that the
+// lexer/parser never populate, so there's no real line/column to
+// print yet.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"grol.io/grol/ast"
+	"grol.io/grol/astmatch"
+	"grol.io/grol/lexer"
+	"grol.io/grol/parser"
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: %s <pattern> <file.gr>\n", os.Args[0])
+	os.Exit(1)
+}
+
+func main() {
+	if len(os.Args) != 3 {
+		usage()
+	}
+	patternSrc, file := os.Args[1], os.Args[2]
+
+	pattern, err := astmatch.Compile(patternSrc)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	p := parser.New(lexer.New(string(data)))
+	prog, err := p.ParseProgram()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	ast.Inspect(prog, func(n ast.Node) bool {
+		if n == nil {
+			return false
+		}
+		if _, ok := pattern.Match(n); ok {
+			fmt.Printf("%s: %s\n", file, ast.DebugString(n))
+		}
+		return true
+	})
+}